@@ -0,0 +1,28 @@
+package snapshot
+
+import (
+	"runtime"
+	"strings"
+)
+
+// packageImportPath is used to recognize and skip stack frames internal to this package when locating the test
+// source file that ultimately triggered a snapshot assertion.
+const packageImportPath = "github.com/BTBurke/snapshot."
+
+// callerOutsidePackage walks the call stack starting at its own caller and returns the first frame that does not
+// belong to this package, i.e. the test code that (directly or indirectly) called Assert.
+func callerOutsidePackage() (runtime.Frame, bool) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, packageImportPath) {
+			return frame, true
+		}
+		if !more {
+			break
+		}
+	}
+	return runtime.Frame{}, false
+}
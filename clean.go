@@ -0,0 +1,162 @@
+package snapshot
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Clean removes snapshots under dir that no longer correspond to a test in the package that produced them,
+// recursing into subdirectories so layouts like MirrorPackageLayout, which nest snapshots under
+// <pkg-rel>/<TestName>/, are cleaned too.  It covers both storage schemes: stale ModeFile snapshots are deleted
+// outright, and stale sections inside ModeMulti ".snaps" files are rewritten out, leaving the rest of the file
+// intact.  A subdirectory named after a dead top-level test is removed wholesale; any other subdirectory left
+// empty by pruning is removed along with it.  Liveness is determined by running `go test -list` against the
+// package containing dir, so Clean must be called with the snapshot directory that sits alongside (or under)
+// that package's source.
+func Clean(dir string) error {
+	live, err := liveTestNames(filepath.Dir(dir))
+	if err != nil {
+		return fmt.Errorf("unable to determine live tests: %w", err)
+	}
+	return cleanDir(dir, live)
+}
+
+// cleanDir applies Clean's pruning rules to every entry in dir, recursing into subdirectories first and removing
+// any that end up empty.  A directory whose name is itself a test name (as MirrorPackageLayout creates) is
+// special-cased: go test -list only enumerates top-level tests, so the directory's liveness is decided by that
+// name alone rather than by the subtest files inside it, and it's kept or removed as a whole.
+func cleanDir(dir string, live map[string]bool) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		full := path.Join(dir, e.Name())
+		if e.IsDir() {
+			if isTestNameDir(e.Name()) {
+				if !live[e.Name()] {
+					if err := os.RemoveAll(full); err != nil {
+						return fmt.Errorf("removing %s: %w", full, err)
+					}
+					recordResult(resultObsolete)
+				}
+				continue
+			}
+			if err := cleanDir(full, live); err != nil {
+				return err
+			}
+			remaining, err := ioutil.ReadDir(full)
+			if err != nil {
+				return err
+			}
+			if len(remaining) == 0 {
+				if err := os.Remove(full); err != nil {
+					return fmt.Errorf("removing empty directory %s: %w", full, err)
+				}
+			}
+			continue
+		}
+		name := e.Name()
+		if strings.HasSuffix(name, ".snaps") {
+			if err := cleanMultiFile(full, live); err != nil {
+				return fmt.Errorf("cleaning %s: %w", full, err)
+			}
+			continue
+		}
+		if isObsoleteFlatSnapshot(name, live) {
+			if err := os.Remove(full); err != nil {
+				return fmt.Errorf("removing %s: %w", full, err)
+			}
+			recordResult(resultObsolete)
+		}
+	}
+	return nil
+}
+
+// isTestNameDir reports whether name looks like a Go test function name (e.g. "TestFoo"), the same convention
+// MirrorPackageLayout uses for a test's snapshot directory: "Test" followed by a rune that isn't lowercase, per
+// the rule `go test` itself uses to recognize test functions.  An ordinary package-path directory component
+// practically never matches this, since Go package names are conventionally all lowercase.
+func isTestNameDir(name string) bool {
+	const prefix = "Test"
+	if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(name[len(prefix):])
+	return r != utf8.RuneError && !unicode.IsLower(r)
+}
+
+// liveTestNames runs `go test -list` in pkgDir and returns the set of test function names currently defined in
+// that package.
+func liveTestNames(pkgDir string) (map[string]bool, error) {
+	cmd := exec.Command("go", "test", "-list", ".*", ".")
+	cmd.Dir = pkgDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	live := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "ok") || strings.HasPrefix(line, "---") {
+			continue
+		}
+		live[line] = true
+	}
+	return live, nil
+}
+
+// isObsoleteFlatSnapshot reports whether a ModeFile snapshot file name no longer corresponds to any live test.
+// live only holds top-level test names (go test -list doesn't enumerate subtests), so a subtest snapshot like
+// "testfoo-sub.snap" is kept alive by its parent test "TestFoo" the same way a top-level snapshot is kept alive
+// by an exact match — but the match must stop at that "-" boundary, or an unrelated, genuinely deleted test whose
+// sanitized name happens to start with a live one's (e.g. "TestFooBar" vs. live "TestFoo") would never be pruned.
+func isObsoleteFlatSnapshot(filename string, live map[string]bool) bool {
+	base := strings.TrimSuffix(filename, filepath.Ext(filename))
+	for name := range live {
+		stem := getSnapFilename(name, "")
+		if base == stem || strings.HasPrefix(base, stem+"-") {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanMultiFile rewrites p, keeping only sections whose owning top-level test is still live.  A section's key
+// is "<TestName>[/<subtest>]#<counter>"; live only holds top-level names, so the counter and any subtest path
+// must both be stripped before comparing.
+func cleanMultiFile(p string, live map[string]bool) error {
+	sections, err := readMultiSnapshot(p)
+	if err != nil {
+		return err
+	}
+	kept := sections[:0]
+	for _, s := range sections {
+		name := s.key
+		if i := strings.LastIndex(name, "#"); i >= 0 {
+			name = name[:i]
+		}
+		if i := strings.IndexByte(name, '/'); i >= 0 {
+			name = name[:i]
+		}
+		if !live[name] {
+			recordResult(resultObsolete)
+			continue
+		}
+		kept = append(kept, s)
+	}
+	if len(kept) == 0 {
+		return os.Remove(p)
+	}
+	return writeMultiSnapshot(p, kept)
+}
@@ -0,0 +1,102 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIsObsoleteFlatSnapshot(t *testing.T) {
+	live := map[string]bool{"TestFoo": true}
+
+	cases := []struct {
+		name     string
+		filename string
+		obsolete bool
+	}{
+		{"exact match", "testfoo.snap", false},
+		{"subtest of live test", "testfoo-sub.snap", false},
+		{"unrelated test sharing a prefix", "testfoobar.snap", true},
+		{"different extension", "testfoo.snapshot", false},
+		{"deleted test", "testbaz.snap", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isObsoleteFlatSnapshot(tc.filename, live); got != tc.obsolete {
+				t.Fatalf("isObsoleteFlatSnapshot(%q) = %v, want %v", tc.filename, got, tc.obsolete)
+			}
+		})
+	}
+}
+
+func TestCleanMultiFileKeepsLiveSubtests(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "pkg_test.go.snaps")
+	sections := []multiSection{
+		{key: "TestFoo/sub#1", body: []byte("kept\n")},
+		{key: "TestDeleted#1", body: []byte("pruned\n")},
+	}
+	if err := writeMultiSnapshot(p, sections); err != nil {
+		t.Fatalf("writeMultiSnapshot: %s", err)
+	}
+
+	live := map[string]bool{"TestFoo": true}
+	if err := cleanMultiFile(p, live); err != nil {
+		t.Fatalf("cleanMultiFile: %s", err)
+	}
+
+	got, err := readMultiSnapshot(p)
+	if err != nil {
+		t.Fatalf("readMultiSnapshot: %s", err)
+	}
+	if len(got) != 1 || got[0].key != "TestFoo/sub#1" {
+		t.Fatalf("expected only the live subtest's section to survive, got %+v", got)
+	}
+}
+
+func TestCleanDirRecursesAndRemovesDeadTestDirs(t *testing.T) {
+	dir := t.TempDir()
+
+	liveDir := filepath.Join(dir, "pkg", "TestFoo")
+	if err := os.MkdirAll(liveDir, 0755); err != nil {
+		t.Fatalf("mkdir: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(liveDir, "sub.snap"), []byte("kept"), 0644); err != nil {
+		t.Fatalf("write: %s", err)
+	}
+
+	deadDir := filepath.Join(dir, "pkg", "TestDeleted")
+	if err := os.MkdirAll(deadDir, 0755); err != nil {
+		t.Fatalf("mkdir: %s", err)
+	}
+	if err := os.WriteFile(filepath.Join(deadDir, "sub.snap"), []byte("pruned"), 0644); err != nil {
+		t.Fatalf("write: %s", err)
+	}
+
+	live := map[string]bool{"TestFoo": true}
+	if err := cleanDir(dir, live); err != nil {
+		t.Fatalf("cleanDir: %s", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(liveDir, "sub.snap")); err != nil {
+		t.Fatalf("expected live test's nested snapshot to survive: %s", err)
+	}
+	if _, err := os.Stat(deadDir); !os.IsNotExist(err) {
+		t.Fatalf("expected the deleted test's directory to be removed, stat err = %v", err)
+	}
+}
+
+func TestCleanMultiFileRemovesFileWhenAllSectionsObsolete(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "pkg_test.go.snaps")
+	sections := []multiSection{{key: "TestDeleted#1", body: []byte("pruned\n")}}
+	if err := writeMultiSnapshot(p, sections); err != nil {
+		t.Fatalf("writeMultiSnapshot: %s", err)
+	}
+
+	if err := cleanMultiFile(p, map[string]bool{}); err != nil {
+		t.Fatalf("cleanMultiFile: %s", err)
+	}
+	if _, err := readMultiSnapshot(p); err == nil {
+		t.Fatalf("expected .snaps file to be removed once every section is obsolete")
+	}
+}
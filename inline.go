@@ -0,0 +1,182 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// AssertInline compares got to want, the string literal passed as the third argument of this very call, failing
+// the test on a mismatch unless UPDATE_SNAPSHOTS allows rewriting it.  Unlike Assert, whose expected value lives
+// in a separate snapshot file, AssertInline keeps it at the call site, the way some other snapshot libraries
+// "inline" their expected values:
+//
+//	snapshot.AssertInline(t, fmt.Sprintf("%d apples", n), `3 apples`)
+//
+// Pass "" for want the first time; run with UPDATE_SNAPSHOTS=new (the default) to have it filled in, or
+// UPDATE_SNAPSHOTS=all to rewrite want after a mismatch.  got is rendered the same way Assert renders its
+// argument: a registered Serializer wins if one matches got's type, a []byte is used as-is, and anything else is
+// rendered with a spew dump.
+func AssertInline(t testing.TB, got interface{}, want string) {
+	c, err := New()
+	if err != nil {
+		t.Fatalf("Unable to create new snapshot config: %s", err)
+	}
+	c.AssertInline(t, got, want)
+}
+
+// AssertInline is the Config-bound version of the package-level AssertInline.
+func (c *Config) AssertInline(t testing.TB, got interface{}, want string) {
+	t.Helper()
+	c.assertInline(t, c.renderBytes(t, got), want)
+}
+
+// inlineAction is the outcome of comparing an inline snapshot's current want literal against the freshly
+// rendered b, decided without touching the filesystem so the decision itself stays easy to test.
+type inlineAction int
+
+const (
+	inlineActionMatch inlineAction = iota
+	inlineActionCreate
+	inlineActionOverwrite
+	inlineActionFailMissing
+	inlineActionFailCI
+	inlineActionFailMismatch
+)
+
+// decideInlineAction decides what AssertInline should do with b given the want literal already at the call site
+// and the active UpdateMode.
+func decideInlineAction(mode UpdateMode, want string, b []byte) inlineAction {
+	if want != "" && bytes.Equal([]byte(want), b) {
+		return inlineActionMatch
+	}
+	if want == "" {
+		switch {
+		case mode == UpdateCI:
+			return inlineActionFailCI
+		case mode.canCreate():
+			return inlineActionCreate
+		default:
+			return inlineActionFailMissing
+		}
+	}
+	if mode.canOverwrite() {
+		return inlineActionOverwrite
+	}
+	return inlineActionFailMismatch
+}
+
+// assertInline implements AssertInline once got has been rendered to b.
+func (c *Config) assertInline(t testing.TB, b []byte, want string) {
+	t.Helper()
+
+	switch decideInlineAction(currentUpdateMode(), want, b) {
+	case inlineActionMatch:
+		recordResult(resultMatched)
+		return
+	case inlineActionFailCI:
+		t.Fatalf("No inline snapshot recorded for %s and UPDATE_SNAPSHOTS=ci: a baseline must be committed before running in CI.", t.Name())
+	case inlineActionFailMissing:
+		t.Fatalf("No inline snapshot recorded for %s and UPDATE_SNAPSHOTS=%s.  Failing.", t.Name(), currentUpdateMode())
+	case inlineActionFailMismatch:
+		diff, err := getDiff([]byte(want), b, c.Context)
+		if err != nil {
+			t.Fatalf("Unable to compare inline snapshot to test output: %s", err)
+		}
+		if c.ignore != nil && c.ignore.FindStringIndex(diff) != nil {
+			recordResult(resultMatched)
+			return
+		}
+		t.Fatalf("Snapshot test failed for: %s.  Diff:\n\n%s", t.Name(), diff)
+	}
+
+	frame, ok := callerOutsidePackage()
+	if !ok {
+		t.Fatalf("snapshot: unable to locate calling test source for inline mode")
+	}
+	if err := writeInlineLiteral(frame.File, frame.Line, b); err != nil {
+		t.Fatalf("Unable to write inline snapshot: %s", err)
+	}
+	if want == "" {
+		recordResult(resultCreated)
+		return
+	}
+	recordResult(resultUpdated)
+}
+
+// findInlineWantLiteral returns the want string literal of the AssertInline call site on line: its last
+// argument.  Looking at the whole call, rather than just taking the first string literal on line, matters
+// because got is very often a string literal too (e.g. AssertInline(t, "computed value", `want`)), and the
+// original implementation this replaced picked whichever one came first.
+func findInlineWantLiteral(fset *token.FileSet, file *ast.File, line int) *ast.BasicLit {
+	var found *ast.BasicLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		start, end := fset.Position(call.Pos()).Line, fset.Position(call.End()).Line
+		if line < start || line > end {
+			return true
+		}
+		if callName(call.Fun) != "AssertInline" || len(call.Args) == 0 {
+			return true
+		}
+		lit, ok := call.Args[len(call.Args)-1].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		found = lit
+		return false
+	})
+	return found
+}
+
+// callName returns the identifier an AssertInline call was made through, e.g. "AssertInline" for both
+// AssertInline(...) and c.AssertInline(...).
+func callName(fn ast.Expr) string {
+	switch f := fn.(type) {
+	case *ast.Ident:
+		return f.Name
+	case *ast.SelectorExpr:
+		return f.Sel.Name
+	}
+	return ""
+}
+
+func writeInlineLiteral(file string, line int, b []byte) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	lit := findInlineWantLiteral(fset, f, line)
+	if lit == nil {
+		return fmt.Errorf("no AssertInline want literal found at %s:%d to update", file, line)
+	}
+	lit.Value = inlineLiteralValue(b)
+
+	buf := new(bytes.Buffer)
+	if err := format.Node(buf, fset, f); err != nil {
+		return err
+	}
+	return os.WriteFile(file, buf.Bytes(), 0644)
+}
+
+// inlineLiteralValue renders b as a Go string literal, preferring a backtick raw string unless b contains a
+// backtick or carriage return that would make that impossible to represent.
+func inlineLiteralValue(b []byte) string {
+	if bytes.ContainsRune(b, '`') || bytes.ContainsRune(b, '\r') {
+		return strconv.Quote(string(b))
+	}
+	return "`" + string(b) + "`"
+}
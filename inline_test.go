@@ -0,0 +1,99 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeInlineFixture(t *testing.T) (path string, callLine int) {
+	t.Helper()
+	path = filepath.Join(t.TempDir(), "fixture.go")
+	src := `package fixture
+
+func example() {
+	snapshot.AssertInline(t, "computed value", ` + "`original value`" + `)
+}
+`
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatalf("write fixture: %s", err)
+	}
+	return path, 4
+}
+
+func TestWriteInlineLiteralTargetsWantArgNotGotArg(t *testing.T) {
+	path, line := writeInlineFixture(t)
+
+	if err := writeInlineLiteral(path, line, []byte("updated value")); err != nil {
+		t.Fatalf("writeInlineLiteral: %s", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read fixture: %s", err)
+	}
+	if !strings.Contains(string(got), "`updated value`") {
+		t.Fatalf("expected want literal to be rewritten, got:\n%s", got)
+	}
+	if !strings.Contains(string(got), `"computed value"`) {
+		t.Fatalf("expected got argument to be left untouched, got:\n%s", got)
+	}
+}
+
+func TestWriteInlineLiteralNoCallOnLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixture.go")
+	src := "package fixture\n\nfunc example() {}\n"
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatalf("write fixture: %s", err)
+	}
+	if err := writeInlineLiteral(path, 3, []byte("x")); err == nil {
+		t.Fatalf("expected an error when no AssertInline call is found on the target line")
+	}
+}
+
+func TestInlineLiteralValueEscaping(t *testing.T) {
+	cases := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"plain text", []byte("hello"), "`hello`"},
+		{"contains backtick", []byte("has ` a backtick"), "\"has ` a backtick\""},
+		{"contains carriage return", []byte("line\r\n"), "\"line\\r\\n\""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := inlineLiteralValue(tc.in); got != tc.want {
+				t.Fatalf("inlineLiteralValue(%q) = %s, want %s", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDecideInlineAction(t *testing.T) {
+	cases := []struct {
+		name string
+		mode UpdateMode
+		want string
+		b    []byte
+		out  inlineAction
+	}{
+		{"match regardless of mode", UpdateNone, "same", []byte("same"), inlineActionMatch},
+		{"missing, default mode creates", UpdateNew, "", []byte("new"), inlineActionCreate},
+		{"missing, all creates", UpdateAll, "", []byte("new"), inlineActionCreate},
+		{"missing, none fails", UpdateNone, "", []byte("new"), inlineActionFailMissing},
+		{"missing, ci fails", UpdateCI, "", []byte("new"), inlineActionFailCI},
+		{"mismatch, default mode fails", UpdateNew, "old", []byte("new"), inlineActionFailMismatch},
+		{"mismatch, none fails", UpdateNone, "old", []byte("new"), inlineActionFailMismatch},
+		{"mismatch, ci fails", UpdateCI, "old", []byte("new"), inlineActionFailMismatch},
+		{"mismatch, all overwrites", UpdateAll, "old", []byte("new"), inlineActionOverwrite},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := decideInlineAction(tc.mode, tc.want, tc.b); got != tc.out {
+				t.Fatalf("decideInlineAction(%v, %q, %q) = %v, want %v", tc.mode, tc.want, tc.b, got, tc.out)
+			}
+		})
+	}
+}
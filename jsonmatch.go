@@ -0,0 +1,173 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Matcher substitutes or validates a single field of a JSON document before MatchJSON compares it to (or records
+// it in) a snapshot.  The match package provides the common implementations: match.Any, match.Type and
+// match.Custom.
+type Matcher interface {
+	// Path returns the dotted JSON path this matcher applies to, e.g. "user.id" or "items[0].createdAt".
+	Path() string
+	// Apply is called with the decoded value found at Path and returns the value to substitute in its place, or
+	// an error if the field fails validation.
+	Apply(v interface{}) (interface{}, error)
+}
+
+// MatchJSON canonicalizes the JSON document in b and compares it to the saved snapshot the same way Assert does,
+// except that fields identified by matchers are first replaced (or validated) in place.  This makes it possible
+// to snapshot API responses containing fields that legitimately change between runs, such as generated IDs or
+// timestamps, without those fields causing spurious failures.
+func MatchJSON(t testing.TB, b []byte, matchers ...Matcher) {
+	t.Helper()
+	c, err := New()
+	if err != nil {
+		t.Fatalf("Unable to create new snapshot config: %s", err)
+	}
+	c.MatchJSON(t, b, matchers...)
+}
+
+// MatchJSON is the Config-bound version of the package-level MatchJSON.  See New for custom configuration
+// options such as where to save snapshots.
+func (c *Config) MatchJSON(t testing.TB, b []byte, matchers ...Matcher) {
+	t.Helper()
+
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		t.Fatalf("Unable to decode JSON for snapshot: %s", err)
+	}
+
+	for _, m := range matchers {
+		if err := applyMatcher(doc, m); err != nil {
+			t.Fatalf("Matcher for path %q failed: %s", m.Path(), err)
+		}
+	}
+
+	// encoding/json already marshals map keys in sorted order, so re-marshaling here is enough to make the
+	// recorded snapshot (and any diff against it) deterministic regardless of the original field order.
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		t.Fatalf("Unable to re-marshal JSON for snapshot: %s", err)
+	}
+	c.Assert(t, out)
+}
+
+type pathSegment struct {
+	key   string
+	index int
+	isIdx bool
+}
+
+func parsePath(p string) ([]pathSegment, error) {
+	var segs []pathSegment
+	for _, part := range strings.Split(p, ".") {
+		key := part
+		for {
+			i := strings.IndexByte(key, '[')
+			if i < 0 {
+				if key != "" {
+					segs = append(segs, pathSegment{key: key})
+				}
+				break
+			}
+			if i > 0 {
+				segs = append(segs, pathSegment{key: key[:i]})
+			}
+			j := strings.IndexByte(key, ']')
+			if j < i {
+				return nil, fmt.Errorf("invalid path segment %q", part)
+			}
+			idx, err := strconv.Atoi(key[i+1 : j])
+			if err != nil {
+				return nil, fmt.Errorf("invalid array index in %q: %w", part, err)
+			}
+			segs = append(segs, pathSegment{index: idx, isIdx: true})
+			key = key[j+1:]
+		}
+	}
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return segs, nil
+}
+
+// applyMatcher walks doc to the field identified by m.Path(), replacing it with the result of m.Apply.
+func applyMatcher(doc interface{}, m Matcher) error {
+	segs, err := parsePath(m.Path())
+	if err != nil {
+		return err
+	}
+
+	cur := doc
+	for i, seg := range segs[:len(segs)-1] {
+		next, err := descend(cur, seg)
+		if err != nil {
+			return fmt.Errorf("segment %d: %w", i, err)
+		}
+		cur = next
+	}
+
+	last := segs[len(segs)-1]
+	switch v := cur.(type) {
+	case map[string]interface{}:
+		if last.isIdx {
+			return fmt.Errorf("expected object key, got array index")
+		}
+		val, ok := v[last.key]
+		if !ok {
+			return fmt.Errorf("key %q not found", last.key)
+		}
+		replaced, err := m.Apply(val)
+		if err != nil {
+			return err
+		}
+		v[last.key] = replaced
+	case []interface{}:
+		if !last.isIdx {
+			return fmt.Errorf("expected array index, got object key")
+		}
+		if last.index < 0 || last.index >= len(v) {
+			return fmt.Errorf("index %d out of range", last.index)
+		}
+		replaced, err := m.Apply(v[last.index])
+		if err != nil {
+			return err
+		}
+		v[last.index] = replaced
+	default:
+		return fmt.Errorf("cannot descend into %T", cur)
+	}
+	return nil
+}
+
+func descend(cur interface{}, seg pathSegment) (interface{}, error) {
+	switch v := cur.(type) {
+	case map[string]interface{}:
+		if seg.isIdx {
+			return nil, fmt.Errorf("expected object key, got array index")
+		}
+		val, ok := v[seg.key]
+		if !ok {
+			return nil, fmt.Errorf("key %q not found", seg.key)
+		}
+		return val, nil
+	case []interface{}:
+		if !seg.isIdx {
+			return nil, fmt.Errorf("expected array index, got object key")
+		}
+		if seg.index < 0 || seg.index >= len(v) {
+			return nil, fmt.Errorf("index %d out of range", seg.index)
+		}
+		return v[seg.index], nil
+	default:
+		return nil, fmt.Errorf("cannot descend into %T", cur)
+	}
+}
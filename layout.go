@@ -0,0 +1,60 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// LayoutFunc decides where a test's snapshot lives under Config.Directory.  It returns a directory relative to
+// Directory and a base filename (without the configured Extension, which Assert appends).
+type LayoutFunc func(testName string) (relDir, filename string)
+
+// SnapLayout sets the layout used to place snapshot files under Directory.  The default (nil) is the legacy flat
+// layout: one file per test, directly in Directory, named after the sanitized test name.
+func SnapLayout(fn LayoutFunc) ConfigOption {
+	return func(c *Config) error {
+		c.layout = fn
+		return nil
+	}
+}
+
+// MirrorPackageLayout is a LayoutFunc that stores snapshots at __snapshots__/<pkg-rel>/<TestName>/<subtest>.snap,
+// where <pkg-rel> is the calling test's package directory relative to the current working directory.  This keeps
+// snapshots co-located with the code that produces them and avoids collisions when two packages in the same
+// module have identically named tests.
+func MirrorPackageLayout(testName string) (relDir, filename string) {
+	pkgRel := "."
+	if frame, ok := callerOutsidePackage(); ok {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, filepath.Dir(frame.File)); err == nil {
+				pkgRel = rel
+			}
+		}
+	}
+
+	top, sub, hasSub := strings.Cut(testName, "/")
+	if !hasSub {
+		sub = top
+	}
+	return filepath.Join(pkgRel, top), getSnapFilename(sub, "")
+}
+
+var (
+	legacyWarnMu sync.Mutex
+	legacyWarned = map[string]bool{}
+)
+
+// warnLegacyLayoutOnce logs, at most once per snapshot path per process, that a snapshot was found at its legacy
+// flat location instead of the configured layout.
+func warnLegacyLayoutOnce(t testing.TB, legacyPath string) {
+	legacyWarnMu.Lock()
+	defer legacyWarnMu.Unlock()
+	if legacyWarned[legacyPath] {
+		return
+	}
+	legacyWarned[legacyPath] = true
+	t.Logf("snapshot: %s found at the legacy flat location; it will move to the new layout next time UPDATE_SNAPSHOTS=all is set", legacyPath)
+}
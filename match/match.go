@@ -0,0 +1,79 @@
+// Package match provides Matcher implementations for snapshot.MatchJSON: substitution of fields that legitimately
+// change between runs (match.Any, match.Type) and arbitrary field-level validation (match.Custom).
+package match
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/BTBurke/snapshot"
+)
+
+// anyMatcher replaces its field with a placeholder unconditionally.
+type anyMatcher struct {
+	path string
+}
+
+func (m anyMatcher) Path() string { return m.path }
+
+func (m anyMatcher) Apply(v interface{}) (interface{}, error) {
+	return "<<PRESENT>>", nil
+}
+
+// Any returns a matcher for each path that replaces the value found there with a stable placeholder before the
+// document is compared or recorded, without checking its type or value.  Spread the result into MatchJSON:
+//
+//	snapshot.MatchJSON(t, b, match.Any("user.id", "created_at")...)
+func Any(paths ...string) []snapshot.Matcher {
+	out := make([]snapshot.Matcher, len(paths))
+	for i, p := range paths {
+		out[i] = anyMatcher{path: p}
+	}
+	return out
+}
+
+// typeMatcher asserts that a field decodes into T, then replaces it with a placeholder.
+type typeMatcher[T any] struct {
+	path string
+}
+
+func (m typeMatcher[T]) Path() string { return m.path }
+
+func (m typeMatcher[T]) Apply(v interface{}) (interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("re-marshal value at %q: %w", m.path, err)
+	}
+	var out T
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("value at %q is not a %T: %w", m.path, out, err)
+	}
+	return fmt.Sprintf("<<TYPE:%T>>", out), nil
+}
+
+// Type returns a matcher that fails unless the field at path decodes into T, then replaces it with a stable
+// placeholder.
+func Type[T any](path string) snapshot.Matcher {
+	return typeMatcher[T]{path: path}
+}
+
+// customMatcher runs an arbitrary validator against its field.
+type customMatcher struct {
+	path string
+	fn   func(v interface{}) error
+}
+
+func (m customMatcher) Path() string { return m.path }
+
+func (m customMatcher) Apply(v interface{}) (interface{}, error) {
+	if err := m.fn(v); err != nil {
+		return nil, err
+	}
+	return "<<CUSTOM>>", nil
+}
+
+// Custom returns a matcher that runs fn against the field at path.  If fn returns an error, MatchJSON fails the
+// test; otherwise the field is replaced with a stable placeholder before comparison.
+func Custom(path string, fn func(v interface{}) error) snapshot.Matcher {
+	return customMatcher{path: path, fn: fn}
+}
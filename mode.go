@@ -0,0 +1,23 @@
+package snapshot
+
+// Mode selects where snapshots belonging to a test are stored.
+//
+// Inline snapshots are not a Mode: use AssertInline directly instead of Assert, since comparing an inline
+// snapshot needs the expected value passed in alongside the produced one rather than inferred from c.
+type Mode int
+
+const (
+	// ModeFile stores one snapshot per test as a standalone file in the snapshot directory.  This is the default.
+	ModeFile Mode = iota
+	// ModeMulti appends snapshots for every test in a source file to a single "<file>.snaps" file in the
+	// snapshot directory, keyed by test name and call-site order.
+	ModeMulti
+)
+
+// SnapMode sets the storage mode used to record and compare snapshots.  Defaults to ModeFile.
+func SnapMode(m Mode) ConfigOption {
+	return func(c *Config) error {
+		c.mode = m
+		return nil
+	}
+}
@@ -0,0 +1,219 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	multiHeaderPrefix = "--- snapshot: "
+	multiHeaderSuffix = " ---"
+	multiFooterPrefix = "--- end: "
+	multiFooterSuffix = " ---"
+)
+
+var (
+	multiMu       sync.Mutex
+	multiCounters = map[string]int{}
+)
+
+// multiSection is one key/body pair recorded in a ".snaps" file.
+type multiSection struct {
+	key  string
+	body []byte
+}
+
+// multiFilePath returns the path of the .snaps file that stores every snapshot recorded by tests in sourceFile,
+// inside dir.
+func multiFilePath(dir, sourceFile string) string {
+	return path.Join(dir, filepath.Base(sourceFile)+".snaps")
+}
+
+// nextMultiKey returns the key under which the next call to Assert from (sourceFile, testName) should be stored:
+// the test name followed by a call-site counter that increments each time the same test records another
+// snapshot during this run.
+func nextMultiKey(sourceFile, testName string) string {
+	multiMu.Lock()
+	defer multiMu.Unlock()
+	ck := sourceFile + "#" + testName
+	multiCounters[ck]++
+	return fmt.Sprintf("%s#%d", testName, multiCounters[ck])
+}
+
+// assertMulti compares b to the section recorded for this call site in the test file's .snaps file, creating or
+// updating that section following the same create/compare/update rules as Assert.  It is used when the config's
+// Mode is ModeMulti.
+func (c *Config) assertMulti(t testing.TB, b []byte) {
+	t.Helper()
+
+	frame, ok := callerOutsidePackage()
+	if !ok {
+		t.Fatalf("snapshot: unable to locate calling test source for multi mode")
+	}
+	key := nextMultiKey(frame.File, t.Name())
+	snapPath := multiFilePath(c.Directory, frame.File)
+	mode := currentUpdateMode()
+
+	if _, err := os.Stat(c.Directory); os.IsNotExist(err) {
+		if mode == UpdateCI {
+			t.Fatalf("No snapshot directory exists and UPDATE_SNAPSHOTS=ci: a baseline must be committed before running in CI.")
+		}
+		if !mode.canCreate() {
+			t.Fatalf("No snapshot directory exists and UPDATE_SNAPSHOTS=%s.  Failing.", mode)
+		}
+		if err := os.MkdirAll(c.Directory, os.FileMode(0777)); err != nil {
+			t.Fatalf("Unable to create the snapshot directory, failing")
+		}
+	}
+
+	sections, err := readMultiSnapshot(snapPath)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("Unable to read multi-snapshot file: %s", err)
+	}
+
+	existing, found := findMultiSection(sections, key)
+	switch {
+	case !found:
+		if mode == UpdateCI {
+			t.Fatalf("No snapshot recorded for %s and UPDATE_SNAPSHOTS=ci: a baseline must be committed before running in CI.", key)
+		}
+		if !mode.canCreate() {
+			t.Fatalf("No snapshot recorded for %s and UPDATE_SNAPSHOTS=%s.  Failing.", key, mode)
+		}
+		if err := writeMultiSnapshot(snapPath, upsertMultiSection(sections, key, b)); err != nil {
+			t.Fatalf("Unable to write multi-snapshot file: %s", err)
+		}
+		recordResult(resultCreated)
+		return
+	case bytes.Equal(existing, b):
+		recordResult(resultMatched)
+		return
+	case mode.canOverwrite():
+		if err := writeMultiSnapshot(snapPath, upsertMultiSection(sections, key, b)); err != nil {
+			t.Fatalf("Unable to write multi-snapshot file: %s", err)
+		}
+		recordResult(resultUpdated)
+		return
+	default:
+		diff, err := getDiff(existing, b, c.Context)
+		if err != nil {
+			t.Fatalf("Unable to compare snapshot to test output: %s", err)
+		}
+		if c.ignore != nil && c.ignore.FindStringIndex(diff) != nil {
+			recordResult(resultMatched)
+			return
+		}
+		t.Fatalf("Snapshot test failed for: %s.  Diff:\n\n%s", key, diff)
+	}
+}
+
+func findMultiSection(sections []multiSection, key string) ([]byte, bool) {
+	for _, s := range sections {
+		if s.key == key {
+			return s.body, true
+		}
+	}
+	return nil, false
+}
+
+func upsertMultiSection(sections []multiSection, key string, body []byte) []multiSection {
+	for i, s := range sections {
+		if s.key == key {
+			sections[i].body = body
+			return sections
+		}
+	}
+	return append(sections, multiSection{key: key, body: body})
+}
+
+// readMultiSnapshot parses a .snaps file into its ordered sections.  Each section's header records the exact
+// byte length of its body, so the body round-trips unchanged regardless of what newlines it does or doesn't end
+// with.
+func readMultiSnapshot(p string) ([]multiSection, error) {
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []multiSection
+	rest := data
+	for len(rest) > 0 {
+		nl := bytes.IndexByte(rest, '\n')
+		if nl < 0 {
+			return nil, fmt.Errorf("snapshot: malformed multi-snapshot file %s: unterminated header", p)
+		}
+		key, n, err := parseMultiHeader(string(rest[:nl]))
+		if err != nil {
+			return nil, fmt.Errorf("snapshot: malformed multi-snapshot file %s: %w", p, err)
+		}
+		rest = rest[nl+1:]
+
+		if n > len(rest) {
+			return nil, fmt.Errorf("snapshot: malformed multi-snapshot file %s: section %q truncated", p, key)
+		}
+		body := append([]byte(nil), rest[:n]...)
+		rest = rest[n:]
+
+		if len(rest) == 0 || rest[0] != '\n' {
+			return nil, fmt.Errorf("snapshot: malformed multi-snapshot file %s: section %q missing separator", p, key)
+		}
+		rest = rest[1:]
+
+		footerLine, remainder, ok := cutLine(rest)
+		if !ok || footerLine != multiFooterPrefix+key+multiFooterSuffix {
+			return nil, fmt.Errorf("snapshot: malformed multi-snapshot file %s: section %q missing footer", p, key)
+		}
+		rest = remainder
+
+		sections = append(sections, multiSection{key: key, body: body})
+	}
+	return sections, nil
+}
+
+// cutLine splits b at its first newline, returning the line (without the newline) and the remainder.
+func cutLine(b []byte) (line string, rest []byte, ok bool) {
+	nl := bytes.IndexByte(b, '\n')
+	if nl < 0 {
+		return "", nil, false
+	}
+	return string(b[:nl]), b[nl+1:], true
+}
+
+// parseMultiHeader parses a "--- snapshot: <key> len=<N> ---" header line.
+func parseMultiHeader(line string) (key string, n int, err error) {
+	if !strings.HasPrefix(line, multiHeaderPrefix) || !strings.HasSuffix(line, multiHeaderSuffix) {
+		return "", 0, fmt.Errorf("invalid section header %q", line)
+	}
+	mid := strings.TrimSuffix(strings.TrimPrefix(line, multiHeaderPrefix), multiHeaderSuffix)
+	i := strings.LastIndex(mid, " len=")
+	if i < 0 {
+		return "", 0, fmt.Errorf("invalid section header %q: missing length", line)
+	}
+	n, err = strconv.Atoi(mid[i+len(" len="):])
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid section header %q: %w", line, err)
+	}
+	return mid[:i], n, nil
+}
+
+// writeMultiSnapshot writes sections back to p in order, using the header/footer delimited format Clean also
+// understands.  The header's len= field lets readMultiSnapshot recover the body's exact bytes, including any
+// trailing newline.
+func writeMultiSnapshot(p string, sections []multiSection) error {
+	buf := new(bytes.Buffer)
+	for _, s := range sections {
+		fmt.Fprintf(buf, "%s%s len=%d%s\n", multiHeaderPrefix, s.key, len(s.body), multiHeaderSuffix)
+		buf.Write(s.body)
+		buf.WriteByte('\n')
+		fmt.Fprintf(buf, "%s%s%s\n", multiFooterPrefix, s.key, multiFooterSuffix)
+	}
+	return ioutil.WriteFile(p, buf.Bytes(), os.FileMode(0644))
+}
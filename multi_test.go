@@ -0,0 +1,64 @@
+package snapshot
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestMultiSnapshotRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		body []byte
+	}{
+		{"trailing newline", []byte("hello world\n")},
+		{"no trailing newline", []byte("hello world")},
+		{"multiple trailing newlines", []byte("hello world\n\n\n")},
+		{"empty body", []byte("")},
+		{"embedded newlines", []byte("line one\nline two\nline three\n")},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := filepath.Join(t.TempDir(), "pkg_test.go.snaps")
+			sections := upsertMultiSection(nil, "TestFoo#1", tc.body)
+			if err := writeMultiSnapshot(p, sections); err != nil {
+				t.Fatalf("writeMultiSnapshot: %s", err)
+			}
+			got, err := readMultiSnapshot(p)
+			if err != nil {
+				t.Fatalf("readMultiSnapshot: %s", err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("expected 1 section, got %d", len(got))
+			}
+			if !bytes.Equal(got[0].body, tc.body) {
+				t.Fatalf("round trip mismatch: got %q, want %q", got[0].body, tc.body)
+			}
+		})
+	}
+}
+
+func TestMultiSnapshotRoundTripMultipleSections(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "pkg_test.go.snaps")
+	var sections []multiSection
+	sections = upsertMultiSection(sections, "TestFoo#1", []byte("first\n"))
+	sections = upsertMultiSection(sections, "TestFoo#2", []byte("second"))
+	sections = upsertMultiSection(sections, "TestBar/sub#1", []byte("third\n\n"))
+
+	if err := writeMultiSnapshot(p, sections); err != nil {
+		t.Fatalf("writeMultiSnapshot: %s", err)
+	}
+	got, err := readMultiSnapshot(p)
+	if err != nil {
+		t.Fatalf("readMultiSnapshot: %s", err)
+	}
+	if len(got) != len(sections) {
+		t.Fatalf("expected %d sections, got %d", len(sections), len(got))
+	}
+	for i, want := range sections {
+		if got[i].key != want.key || !bytes.Equal(got[i].body, want.body) {
+			t.Fatalf("section %d mismatch: got %+v, want %+v", i, got[i], want)
+		}
+	}
+}
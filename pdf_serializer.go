@@ -0,0 +1,61 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// PDFTextExtractor extracts the visible text from a PDF document's bytes.  This package does not parse PDFs
+// itself; plug in a function backed by a PDF library so PDFSerializer can diff text content instead of raw PDF
+// bytes, which embed non-deterministic data (creation dates, object IDs, font subsetting) even between runs that
+// produce visually identical documents.
+type PDFTextExtractor func(b []byte) (string, error)
+
+// PDFSerializer snapshots PDF documents by extracting their text via Extract and diffing that text rather than
+// the raw PDF bytes.
+type PDFSerializer struct {
+	Extract PDFTextExtractor
+	// Context is the number of lines of context shown around each extracted-text diff.
+	Context int
+}
+
+// NewPDFSerializer returns a PDFSerializer that uses extract to pull text out of a PDF document before diffing.
+func NewPDFSerializer(extract PDFTextExtractor) *PDFSerializer {
+	return &PDFSerializer{Extract: extract, Context: 3}
+}
+
+// Extension returns ".pdf".
+func (p *PDFSerializer) Extension() string { return ".pdf" }
+
+// Marshal stores the PDF bytes unmodified; text extraction happens lazily in Diff so a byte-identical re-run
+// never needs to invoke Extract at all.
+func (p *PDFSerializer) Marshal(v interface{}) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("PDFSerializer: unsupported value type %T, expected []byte", v)
+	}
+	return b, nil
+}
+
+// Diff extracts text from a and b with Extract and returns a unified diff of that text.  An empty string means
+// the extracted text is identical even though the underlying PDF bytes differ.
+func (p *PDFSerializer) Diff(a, b []byte) (string, error) {
+	if bytes.Equal(a, b) {
+		return "", nil
+	}
+	if p.Extract == nil {
+		return "", fmt.Errorf("PDFSerializer: no text extractor configured")
+	}
+	textA, err := p.Extract(a)
+	if err != nil {
+		return "", fmt.Errorf("extract text from expected PDF: %w", err)
+	}
+	textB, err := p.Extract(b)
+	if err != nil {
+		return "", fmt.Errorf("extract text from received PDF: %w", err)
+	}
+	if textA == textB {
+		return "", nil
+	}
+	return getDiff([]byte(textA), []byte(textB), p.Context)
+}
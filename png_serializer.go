@@ -0,0 +1,115 @@
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// pngDiffBlock is the edge length, in pixels, of each heatmap cell reported by PNGSerializer.Diff.
+const pngDiffBlock = 8
+
+// PNGSerializer serializes image.Image values as PNG and compares them by similarity rather than exact bytes,
+// since re-encoding visually identical pixels can still produce a different byte stream.  Two images are
+// considered equal if the fraction of differing pixels is within Threshold.
+type PNGSerializer struct {
+	// Threshold is the maximum fraction of differing pixels (0-1) tolerated before Diff reports a failure.
+	Threshold float64
+}
+
+// NewPNGSerializer returns a PNGSerializer that tolerates up to threshold fraction of differing pixels (e.g. 0.01
+// allows 1% of pixels to differ) before two images are considered different.
+func NewPNGSerializer(threshold float64) *PNGSerializer {
+	return &PNGSerializer{Threshold: threshold}
+}
+
+// ForImageTypes returns serializer entries that register s for *image.NRGBA, *image.RGBA, *image.Gray and
+// *image.Paletted, so callers don't have to enumerate them by hand; other image.Image types (e.g. *image.YCbCr or
+// *image.RGBA64) are not registered:
+//
+//	snapshot.Serializers(snapshot.ForImageTypes(snapshot.NewPNGSerializer(0.01))...)
+func ForImageTypes(s Serializer) []SerializerEntry {
+	return []SerializerEntry{
+		ForType(&image.NRGBA{}, s),
+		ForType(&image.RGBA{}, s),
+		ForType(&image.Gray{}, s),
+		ForType(&image.Paletted{}, s),
+	}
+}
+
+// Extension returns ".png".
+func (p *PNGSerializer) Extension() string { return ".png" }
+
+// Marshal encodes v, which must implement image.Image, as PNG.
+func (p *PNGSerializer) Marshal(v interface{}) ([]byte, error) {
+	img, ok := v.(image.Image)
+	if !ok {
+		return nil, fmt.Errorf("PNGSerializer: %T does not implement image.Image", v)
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Diff decodes a and b as PNG and compares them pixel by pixel.  If the fraction of differing pixels is within
+// Threshold, Diff returns an empty string.  Otherwise it returns an ASCII heatmap, one character per
+// pngDiffBlock x pngDiffBlock region, where '#' marks a region containing a difference and '.' marks a matching
+// one.
+func (p *PNGSerializer) Diff(a, b []byte) (string, error) {
+	imgA, err := png.Decode(bytes.NewReader(a))
+	if err != nil {
+		return "", fmt.Errorf("decode expected PNG: %w", err)
+	}
+	imgB, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		return "", fmt.Errorf("decode received PNG: %w", err)
+	}
+
+	boundsA, boundsB := imgA.Bounds(), imgB.Bounds()
+	if boundsA != boundsB {
+		return fmt.Sprintf("image dimensions differ: expected %v, got %v", boundsA, boundsB), nil
+	}
+
+	var heatmap strings.Builder
+	var total, diffPixels int
+	for y := boundsA.Min.Y; y < boundsA.Max.Y; y += pngDiffBlock {
+		for bx := boundsA.Min.X; bx < boundsA.Max.X; bx += pngDiffBlock {
+			differs := false
+			for py := y; py < y+pngDiffBlock && py < boundsA.Max.Y; py++ {
+				for px := bx; px < bx+pngDiffBlock && px < boundsA.Max.X; px++ {
+					total++
+					if !pixelsEqual(imgA.At(px, py), imgB.At(px, py)) {
+						diffPixels++
+						differs = true
+					}
+				}
+			}
+			if differs {
+				heatmap.WriteByte('#')
+			} else {
+				heatmap.WriteByte('.')
+			}
+		}
+		heatmap.WriteByte('\n')
+	}
+
+	if total == 0 {
+		return "", nil
+	}
+	ratio := float64(diffPixels) / float64(total)
+	if ratio <= p.Threshold {
+		return "", nil
+	}
+	return fmt.Sprintf("images differ in %.2f%% of pixels (threshold %.2f%%):\n%s", ratio*100, p.Threshold*100, heatmap.String()), nil
+}
+
+func pixelsEqual(a, b color.Color) bool {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+	return ar == br && ag == bg && ab == bb && aa == ba
+}
@@ -0,0 +1,52 @@
+package snapshot
+
+import "reflect"
+
+// Serializer converts a test value into the bytes its snapshot is recorded as, and knows how to explain any
+// difference between two such recordings.  Register one with the Serializers config option to support a value
+// type beyond what the default spew dump handles, e.g. images or PDFs that need a similarity-based diff instead
+// of an exact byte comparison.
+type Serializer interface {
+	// Marshal renders v into the bytes that will be written to (or compared against) the snapshot file.
+	Marshal(v interface{}) ([]byte, error)
+	// Diff explains why the recorded snapshot (a) and the current value (b) differ.  An empty string means they
+	// should be treated as equal even though their bytes differ, which lets a serializer implement a similarity
+	// threshold instead of requiring an exact match.
+	Diff(a, b []byte) (string, error)
+	// Extension is the snapshot file extension this serializer's output should be saved with, including the
+	// leading dot.
+	Extension() string
+}
+
+// SerializerEntry pairs a Serializer with a sample value of the concrete type it should handle.
+type SerializerEntry struct {
+	Sample     interface{}
+	Serializer Serializer
+}
+
+// ForType returns a SerializerEntry that registers s to handle values with the same concrete type as sample.
+func ForType(sample interface{}, s Serializer) SerializerEntry {
+	return SerializerEntry{Sample: sample, Serializer: s}
+}
+
+// Serializers registers additional serializers.  AssertValue chooses among them by the concrete type of the
+// value passed to Assert: when it matches a registered sample's type, that serializer is used instead of the
+// default spew dump.
+func Serializers(entries ...SerializerEntry) ConfigOption {
+	return func(c *Config) error {
+		if c.serializers == nil {
+			c.serializers = map[reflect.Type]Serializer{}
+		}
+		for _, e := range entries {
+			c.serializers[reflect.TypeOf(e.Sample)] = e.Serializer
+		}
+		return nil
+	}
+}
+
+func (c *Config) serializerFor(v interface{}) Serializer {
+	if c.serializers == nil {
+		return nil
+	}
+	return c.serializers[reflect.TypeOf(v)]
+}
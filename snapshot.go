@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
 	"regexp"
 	"strings"
 	"testing"
@@ -26,6 +27,13 @@ type Config struct {
 	Extension string
 	// a regex diff to ignore (for stateful diffs, e.g., creation dates)
 	ignore *regexp.Regexp
+	// storage mode: one file per test (ModeFile) or multiple tests appended to one file (ModeMulti); inline
+	// snapshots are not a storage mode and are recorded through AssertInline instead
+	mode Mode
+	// serializers registered via the Serializers option, keyed by the concrete type they handle
+	serializers map[reflect.Type]Serializer
+	// layout controls where a test's snapshot file lives under Directory; nil means the legacy flat layout
+	layout LayoutFunc
 }
 
 // ConfigOption is a functional option that sets config values
@@ -102,98 +110,186 @@ func SnapExtension(ext string) ConfigOption {
 
 // Assert compares the output in b to the snapshot saved for the current test.  If the snapshot file does not
 // yet exist for this test, it will be created and the test will pass.  If the snapshot file exists and the test
-// output does not match, the test will fail and a diff will be shown.  To update your snapshots, set
-// `UPDATE_SNAPSHOTS=true` when running your test suite.  The default config stores snapshots in `__snapshots__` relative
-// to the test directory.
+// output does not match, the test will fail and a diff will be shown.  UPDATE_SNAPSHOTS controls this behavior:
+// unset (or "new") only creates missing snapshots, "all" also overwrites mismatched ones, "none" never creates or
+// overwrites, and "ci" fails instead of creating a snapshot so a baseline can't be committed by accident from a
+// green CI run.  See UpdateMode.  The default config stores snapshots in `__snapshots__` relative to the test
+// directory.
 func Assert(t testing.TB, b interface{}) {
 	c, err := New()
 	if err != nil {
 		t.Fatalf("Unable to create new snapshot config: %s", err)
 	}
-	switch b.(type) {
-	case []byte:
-		c.Assert(t, b.([]byte))
-	default:
-		buf := new(bytes.Buffer)
-		scs := spew.ConfigState{
-			DisablePointerAddresses: true,
+	c.AssertValue(t, b)
+}
+
+// AssertValue is the Config-bound version of the package-level Assert, dispatching to a registered Serializer
+// when b's concrete type was registered with Serializers, using a []byte as-is, and falling back to a spew dump
+// otherwise.
+func (c *Config) AssertValue(t testing.TB, b interface{}) {
+	t.Helper()
+
+	// Check for a registered serializer first: a []byte value (e.g. a PDF document) may itself have one
+	// registered via Serializers, and it must win over the plain []byte fast path below.
+	if s := c.serializerFor(b); s != nil {
+		out, err := s.Marshal(b)
+		if err != nil {
+			t.Fatalf("Unable to marshal %T with registered serializer: %s", b, err)
 		}
-		scs.Fdump(buf, b)
-		c.Assert(t, buf.Bytes())
+		c.assertBytes(t, out, s.Extension(), s.Diff)
+		return
 	}
 
+	c.Assert(t, c.renderBytes(t, b))
+}
+
+// renderBytes converts v into the bytes that would be recorded for it: a registered Serializer's Marshal output
+// if one matches v's concrete type, v itself if v is already a []byte, or a spew dump otherwise.  It's shared by
+// AssertValue and AssertInline so both render a value the same way.
+func (c *Config) renderBytes(t testing.TB, v interface{}) []byte {
+	t.Helper()
+
+	if s := c.serializerFor(v); s != nil {
+		out, err := s.Marshal(v)
+		if err != nil {
+			t.Fatalf("Unable to marshal %T with registered serializer: %s", v, err)
+		}
+		return out
+	}
+
+	if raw, ok := v.([]byte); ok {
+		return raw
+	}
+
+	buf := new(bytes.Buffer)
+	scs := spew.ConfigState{
+		DisablePointerAddresses: true,
+	}
+	scs.Fdump(buf, v)
+	return buf.Bytes()
 }
 
 // Assert compares the output in b to the snapshot saved for the current test.  If the snapshot file does not
 // yet exist for this test, it will be created and the test will pass.  If the snapshot file exists and the test
-// output does not match, the test will fail and a diff will be shown.  To update your snapshots, set
-// `UPDATE_SNAPSHOTS=true` when running your test suite.
+// output does not match, the test will fail and a diff will be shown.  UPDATE_SNAPSHOTS controls this behavior:
+// unset (or "new") only creates missing snapshots, "all" also overwrites mismatched ones, "none" never creates or
+// overwrites, and "ci" fails instead of creating a snapshot so a baseline can't be committed by accident from a
+// green CI run.  See UpdateMode.
 //
 // See `New` for custom configuration options such as where to save testing snapshots.
 func (c *Config) Assert(t testing.TB, b []byte) {
 	t.Helper()
 
-	// if no snapshot directory exists, fail unless updateable is set
+	if c.mode == ModeMulti {
+		c.assertMulti(t, b)
+		return
+	}
+
+	c.assertBytes(t, b, c.Extension, func(expected, got []byte) (string, error) {
+		return getDiff(expected, got, c.Context)
+	})
+}
+
+// assertBytes is the common ModeFile create/compare/update path, parameterized over the snapshot file extension
+// and the diff function to use when the recorded snapshot doesn't match b.  A diff func returning an empty string
+// is treated as "no meaningful difference" (e.g. a Serializer.Diff that tolerates a similarity threshold).
+func (c *Config) assertBytes(t testing.TB, b []byte, ext string, diff func(expected, got []byte) (string, error)) {
+	t.Helper()
+
+	mode := currentUpdateMode()
+	snapFile := c.snapshotPath(t.Name(), ext)
+
+	// if no snapshot directory exists, fail unless the mode allows creating new snapshots
 	if _, err := os.Stat(c.Directory); os.IsNotExist(err) {
 		switch {
-		case isUpdateable():
+		case mode == UpdateCI:
+			t.Fatalf("No snapshot directory exists and UPDATE_SNAPSHOTS=ci: a baseline must be committed before running in CI.")
+		case mode.canCreate():
 			if err := os.MkdirAll(c.Directory, os.FileMode(0777)); err != nil {
 				t.Fatalf("Unable to create the snapshot directory, failing")
 			}
-			if err := createSnapshot(t.Name(), b, c.Directory, c.Extension); err != nil {
+			if err := createSnapshot(snapFile, b); err != nil {
 				t.Fatalf("Unable to create snapshot: %s", err)
 			}
+			recordResult(resultCreated)
 			return
 		default:
-			t.Fatalf("No snapshot directory exists and UPDATE_SNAPSHOTS=false.  Failing.")
+			t.Fatalf("No snapshot directory exists and UPDATE_SNAPSHOTS=%s.  Failing.", mode)
 		}
 	}
 
-	expected, err := readSnapshot(t.Name(), c.Directory, c.Extension)
+	expected, err := readSnapshot(snapFile)
+	fromLegacy := false
+	if err != nil && c.layout != nil {
+		// new layouts fall back to the legacy flat filename so existing snapshots don't need to be migrated by
+		// hand; the next UPDATE_SNAPSHOTS=all run will write them out under the new layout.
+		legacy := path.Join(c.Directory, getSnapFilename(t.Name(), ext))
+		if legacyExpected, legacyErr := readSnapshot(legacy); legacyErr == nil {
+			warnLegacyLayoutOnce(t, legacy)
+			expected, err = legacyExpected, nil
+			fromLegacy = true
+		}
+	}
 	if err != nil {
-		if err := createSnapshot(t.Name(), b, c.Directory, c.Extension); err != nil {
+		if mode == UpdateCI {
+			t.Fatalf("No snapshot recorded for %s and UPDATE_SNAPSHOTS=ci: a baseline must be committed before running in CI.", t.Name())
+		}
+		if !mode.canCreate() {
+			t.Fatalf("No snapshot recorded for %s and UPDATE_SNAPSHOTS=%s.  Failing.", t.Name(), mode)
+		}
+		if err := createSnapshot(snapFile, b); err != nil {
 			t.Fatalf("Unable to create snapshot: %s", err)
 		}
+		recordResult(resultCreated)
 		return
 	}
 	switch {
 	case bytes.Equal(expected, b):
+		if fromLegacy && mode.canOverwrite() {
+			if err := createSnapshot(snapFile, b); err != nil {
+				t.Fatalf("Unable to create snapshot: %s", err)
+			}
+		}
+		recordResult(resultMatched)
 		return
 	default:
-		if isUpdateable() {
-			if err := createSnapshot(t.Name(), b, c.Directory, c.Extension); err != nil {
+		if mode.canOverwrite() {
+			if err := createSnapshot(snapFile, b); err != nil {
 				t.Fatalf("Unable to create snapshot: %s", err)
 			}
+			recordResult(resultUpdated)
 			return
 		}
 		switch {
 		case c.Diffable:
-			diff, err := getDiff(expected, b, c.Context)
+			d, err := diff(expected, b)
 			if err != nil {
 				t.Fatalf("Unable to compare snapshot to test output: %s", err)
 			}
+			if d == "" {
+				recordResult(resultMatched)
+				return
+			}
 			// check if diff is expected
 			if c.ignore != nil {
-				m := c.ignore.FindStringIndex(diff)
+				m := c.ignore.FindStringIndex(d)
 				if m != nil {
+					recordResult(resultMatched)
 					return
 				}
 			}
-			t.Fatalf("Snapshot test failed for: %s.  Diff:\n\n%s", t.Name(), diff)
+			t.Fatalf("Snapshot test failed for: %s.  Diff:\n\n%s", t.Name(), d)
 		default:
 			t.Fatalf("Snapshot test failed for: %s.  Diff: (undiffable binary format)", t.Name())
 		}
 	}
 }
 
-func isUpdateable() bool {
-	_, ok := os.LookupEnv("UPDATE_SNAPSHOTS")
-	return ok
-}
-
-func createSnapshot(testname string, b []byte, dir string, ext string) error {
-	snapFile := getSnapFilename(testname, ext)
-	f, err := os.Create(path.Join(dir, snapFile))
+func createSnapshot(snapFile string, b []byte) error {
+	if err := os.MkdirAll(path.Dir(snapFile), os.FileMode(0777)); err != nil {
+		return err
+	}
+	f, err := os.Create(snapFile)
 	if err != nil {
 		return err
 	}
@@ -203,8 +299,18 @@ func createSnapshot(testname string, b []byte, dir string, ext string) error {
 	return f.Close()
 }
 
-func readSnapshot(testname string, dir string, ext string) ([]byte, error) {
-	return ioutil.ReadFile(path.Join(dir, getSnapFilename(testname, ext)))
+func readSnapshot(snapFile string) ([]byte, error) {
+	return ioutil.ReadFile(snapFile)
+}
+
+// snapshotPath returns the full path a snapshot for testName should be read from or written to, honoring a
+// custom layout when one is configured.
+func (c *Config) snapshotPath(testName, ext string) string {
+	if c.layout == nil {
+		return path.Join(c.Directory, getSnapFilename(testName, ext))
+	}
+	relDir, filename := c.layout(testName)
+	return path.Join(c.Directory, relDir, filename+ext)
 }
 
 func getSnapFilename(testname string, ext string) string {
@@ -0,0 +1,71 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+)
+
+type resultKind int
+
+const (
+	resultCreated resultKind = iota
+	resultUpdated
+	resultMatched
+	resultObsolete
+)
+
+// summaryCounts is the package-level lifecycle state described in Summary's doc comment, updated from every
+// Assert, MatchJSON and Clean call.
+type summaryCounts struct {
+	Created  int `json:"created"`
+	Updated  int `json:"updated"`
+	Matched  int `json:"matched"`
+	Obsolete int `json:"obsolete"`
+}
+
+var (
+	summaryMu    sync.Mutex
+	summaryState summaryCounts
+)
+
+func recordResult(k resultKind) {
+	summaryMu.Lock()
+	defer summaryMu.Unlock()
+	switch k {
+	case resultCreated:
+		summaryState.Created++
+	case resultUpdated:
+		summaryState.Updated++
+	case resultMatched:
+		summaryState.Matched++
+	case resultObsolete:
+		summaryState.Obsolete++
+	}
+}
+
+// summaryJSONEnv, when set to "1" or "true", makes Summary emit JSON instead of a human-readable line.
+// `go test -json` can't be used for this: the go tool consumes that flag itself (converting the text output via
+// test2json), so the test binary never sees it on os.Args.
+const summaryJSONEnv = "SNAPSHOT_SUMMARY_JSON"
+
+// Summary prints the counts of every snapshot outcome recorded so far by Assert, MatchJSON and Clean: created,
+// updated, matched and (if Clean was used) pruned as obsolete.  Call it from TestMain after m.Run() to get a
+// lifecycle report for the whole test binary.  When the SNAPSHOT_SUMMARY_JSON environment variable is set,
+// Summary emits a single JSON object on stdout instead of the human-readable line, so other tooling can consume
+// it; set it directly rather than relying on `go test -json`, which the go tool strips before the test binary
+// ever runs.
+func Summary() {
+	summaryMu.Lock()
+	s := summaryState
+	summaryMu.Unlock()
+
+	if v, _ := strconv.ParseBool(os.Getenv(summaryJSONEnv)); v {
+		enc := json.NewEncoder(os.Stdout)
+		_ = enc.Encode(s)
+		return
+	}
+	fmt.Printf("snapshot summary: %d created, %d updated, %d matched, %d obsolete\n", s.Created, s.Updated, s.Matched, s.Obsolete)
+}
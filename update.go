@@ -0,0 +1,66 @@
+package snapshot
+
+import (
+	"os"
+	"strings"
+)
+
+// UpdateMode controls how Assert behaves when a snapshot is missing or doesn't match, selected via the
+// UPDATE_SNAPSHOTS environment variable.
+type UpdateMode int
+
+const (
+	// UpdateNew creates snapshots that don't exist yet but fails on a mismatch.  This is the mode used when
+	// UPDATE_SNAPSHOTS is unset, since it's safe to leave on for everyday local development.
+	UpdateNew UpdateMode = iota
+	// UpdateAll creates missing snapshots and overwrites mismatched ones.  Set UPDATE_SNAPSHOTS=all.
+	UpdateAll
+	// UpdateNone never creates or overwrites a snapshot; a missing or mismatched snapshot always fails the test.
+	// Set UPDATE_SNAPSHOTS=none.
+	UpdateNone
+	// UpdateCI treats creation of a missing snapshot as a hard failure, so a baseline can never be committed by
+	// accident from a green CI run.  Mismatches against an existing snapshot still fail as usual.  Set
+	// UPDATE_SNAPSHOTS=ci.
+	UpdateCI
+)
+
+// currentUpdateMode returns the UpdateMode selected by the UPDATE_SNAPSHOTS environment variable.  "true" and "1"
+// are accepted as aliases for "all", the only value this package recognized before UpdateMode existed, so
+// existing Makefiles and CI configs that still set UPDATE_SNAPSHOTS=true keep overwriting snapshots instead of
+// silently dropping to UpdateNew.
+func currentUpdateMode() UpdateMode {
+	switch strings.ToLower(os.Getenv("UPDATE_SNAPSHOTS")) {
+	case "all", "true", "1":
+		return UpdateAll
+	case "none":
+		return UpdateNone
+	case "ci":
+		return UpdateCI
+	default:
+		return UpdateNew
+	}
+}
+
+// canCreate reports whether m allows Assert to write a brand new snapshot for a test that doesn't have one yet.
+func (m UpdateMode) canCreate() bool {
+	return m == UpdateNew || m == UpdateAll
+}
+
+// canOverwrite reports whether m allows Assert to overwrite an existing, mismatched snapshot.
+func (m UpdateMode) canOverwrite() bool {
+	return m == UpdateAll
+}
+
+// String renders m the same way it's spelled in UPDATE_SNAPSHOTS, for use in failure messages.
+func (m UpdateMode) String() string {
+	switch m {
+	case UpdateAll:
+		return "all"
+	case UpdateNone:
+		return "none"
+	case UpdateCI:
+		return "ci"
+	default:
+		return "new"
+	}
+}
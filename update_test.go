@@ -0,0 +1,27 @@
+package snapshot
+
+import "testing"
+
+func TestCurrentUpdateModeLegacyAliases(t *testing.T) {
+	cases := []struct {
+		env  string
+		want UpdateMode
+	}{
+		{"", UpdateNew},
+		{"new", UpdateNew},
+		{"all", UpdateAll},
+		{"true", UpdateAll},
+		{"1", UpdateAll},
+		{"TRUE", UpdateAll},
+		{"none", UpdateNone},
+		{"ci", UpdateCI},
+	}
+	for _, tc := range cases {
+		t.Run(tc.env, func(t *testing.T) {
+			t.Setenv("UPDATE_SNAPSHOTS", tc.env)
+			if got := currentUpdateMode(); got != tc.want {
+				t.Fatalf("currentUpdateMode() with UPDATE_SNAPSHOTS=%q = %v, want %v", tc.env, got, tc.want)
+			}
+		})
+	}
+}